@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackendConfig is a configuration that can be attached to a Kubernetes
+// Service to customize the GCP backend service(s) generated for it, e.g.
+// connection draining, request timeout and retry behavior.
+type BackendConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackendConfigSpec   `json:"spec,omitempty"`
+	Status BackendConfigStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BackendConfigList is a list of BackendConfig resources.
+type BackendConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []BackendConfig `json:"items"`
+}
+
+// BackendConfigSpec is the spec for a BackendConfig resource.
+type BackendConfigSpec struct {
+	// ConnectionDraining configures connection draining for the backend
+	// service(s) generated from the Service this BackendConfig is
+	// attached to.
+	ConnectionDraining *ConnectionDrainingConfig `json:"connectionDraining,omitempty"`
+
+	// TimeoutSec configures the backend service's request timeout, in
+	// seconds. Maps directly onto the GCE BackendService TimeoutSec
+	// field, which otherwise defaults to 30s.
+	TimeoutSec *int64 `json:"timeoutSec,omitempty"`
+
+	// RetryPolicy configures automatic retries for the HTTP(S) load
+	// balancer backed by this BackendConfig.
+	RetryPolicy *RetryPolicyConfig `json:"retryPolicy,omitempty"`
+}
+
+// ConnectionDrainingConfig configures connection draining.
+type ConnectionDrainingConfig struct {
+	// DrainingTimeoutSec is the draining timeout in seconds.
+	DrainingTimeoutSec int64 `json:"drainingTimeoutSec,omitempty"`
+}
+
+// RetryPolicyConfig configures the retry behavior of the load balancer
+// when a request to this backend fails.
+type RetryPolicyConfig struct {
+	// NumRetries is the number of retries to attempt. Must be in the
+	// range [0, 10].
+	NumRetries int32 `json:"numRetries,omitempty"`
+
+	// PerTryTimeoutSec is the timeout applied to each individual retry
+	// attempt, in seconds. Leaving it unset falls back to the backend
+	// service's TimeoutSec for each try.
+	PerTryTimeoutSec int32 `json:"perTryTimeoutSec,omitempty"`
+
+	// RetryConditions are the conditions under which a retry is
+	// attempted: one or more of "5xx", "gateway-error",
+	// "connect-failure", "retriable-4xx".
+	RetryConditions []string `json:"retryConditions,omitempty"`
+}
+
+// BackendConfigStatus is the status for a BackendConfig resource.
+type BackendConfigStatus struct {
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backendconfig
+
+import (
+	"fmt"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+)
+
+// ReasonInvalid is the event reason recorded against a Service when its
+// BackendConfig fails validation.
+const ReasonInvalid = "BackendConfigInvalid"
+
+const (
+	minNumRetries = 0
+	maxNumRetries = 10
+)
+
+// validRetryConditions are the retry conditions accepted in
+// Spec.RetryPolicy.RetryConditions.
+var validRetryConditions = map[string]bool{
+	"5xx":             true,
+	"gateway-error":   true,
+	"connect-failure": true,
+	"retriable-4xx":   true,
+}
+
+// Validate checks the fields of the given BackendConfig and returns an error
+// describing the first invalid field found, if any.
+func Validate(beConfig *backendconfigv1beta1.BackendConfig) error {
+	if err := validateTimeout(beConfig); err != nil {
+		return err
+	}
+	if err := validateRetryPolicy(beConfig); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateTimeout(beConfig *backendconfigv1beta1.BackendConfig) error {
+	if beConfig.Spec.TimeoutSec == nil {
+		return nil
+	}
+	if *beConfig.Spec.TimeoutSec <= 0 {
+		return fmt.Errorf("invalid value for timeoutSec %d, want > 0", *beConfig.Spec.TimeoutSec)
+	}
+	return nil
+}
+
+func validateRetryPolicy(beConfig *backendconfigv1beta1.BackendConfig) error {
+	rp := beConfig.Spec.RetryPolicy
+	if rp == nil {
+		return nil
+	}
+	if rp.NumRetries < minNumRetries || rp.NumRetries > maxNumRetries {
+		return fmt.Errorf("invalid value for retryPolicy.numRetries %d, want in range [%d, %d]", rp.NumRetries, minNumRetries, maxNumRetries)
+	}
+	if rp.PerTryTimeoutSec < 0 {
+		return fmt.Errorf("invalid value for retryPolicy.perTryTimeoutSec %d, want >= 0", rp.PerTryTimeoutSec)
+	}
+	for _, cond := range rp.RetryConditions {
+		if !validRetryConditions[cond] {
+			return fmt.Errorf("invalid retryPolicy.retryConditions value %q, want one of 5xx, gateway-error, connect-failure, retriable-4xx", cond)
+		}
+	}
+	return nil
+}
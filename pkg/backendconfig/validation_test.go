@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backendconfig
+
+import (
+	"testing"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+)
+
+func int64Ptr(i int64) *int64 { return &i }
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		beConfig  *backendconfigv1beta1.BackendConfig
+		expectErr bool
+	}{
+		{
+			desc:     "empty spec is valid",
+			beConfig: &backendconfigv1beta1.BackendConfig{},
+		},
+		{
+			desc: "valid timeout",
+			beConfig: &backendconfigv1beta1.BackendConfig{
+				Spec: backendconfigv1beta1.BackendConfigSpec{TimeoutSec: int64Ptr(60)},
+			},
+		},
+		{
+			desc: "zero timeout is invalid",
+			beConfig: &backendconfigv1beta1.BackendConfig{
+				Spec: backendconfigv1beta1.BackendConfigSpec{TimeoutSec: int64Ptr(0)},
+			},
+			expectErr: true,
+		},
+		{
+			desc: "valid retry policy",
+			beConfig: &backendconfigv1beta1.BackendConfig{
+				Spec: backendconfigv1beta1.BackendConfigSpec{
+					RetryPolicy: &backendconfigv1beta1.RetryPolicyConfig{
+						NumRetries:      3,
+						RetryConditions: []string{"5xx", "gateway-error"},
+					},
+				},
+			},
+		},
+		{
+			desc: "numRetries out of range",
+			beConfig: &backendconfigv1beta1.BackendConfig{
+				Spec: backendconfigv1beta1.BackendConfigSpec{
+					RetryPolicy: &backendconfigv1beta1.RetryPolicyConfig{NumRetries: 11},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			desc: "unknown retry condition",
+			beConfig: &backendconfigv1beta1.BackendConfig{
+				Spec: backendconfigv1beta1.BackendConfigSpec{
+					RetryPolicy: &backendconfigv1beta1.RetryPolicyConfig{
+						RetryConditions: []string{"does-not-exist"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := Validate(tc.beConfig)
+			if (err != nil) != tc.expectErr {
+				t.Errorf("Validate(%+v) = %v, want error: %v", tc.beConfig, err, tc.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/backendconfig"
+	"k8s.io/ingress-gce/pkg/backends/features"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+// ensureBackendConfigFeatures validates beConfig and, if it is valid,
+// applies its Timeout and RetryPolicy settings onto be. If beConfig fails
+// validation, be is left unmodified and a BackendConfigInvalid event is
+// recorded on svc instead, so the invalid config never reaches GCE.
+//
+// It returns true if be was modified and the caller needs to push the
+// update to GCE.
+func ensureBackendConfigFeatures(be *composite.BackendService, beConfig *backendconfigv1beta1.BackendConfig, svc *apiv1.Service, recorder record.EventRecorder) bool {
+	if err := backendconfig.Validate(beConfig); err != nil {
+		recorder.Eventf(svc, apiv1.EventTypeWarning, backendconfig.ReasonInvalid, "Skipping BackendConfig %s/%s for backend service %s: %v", beConfig.Namespace, beConfig.Name, be.Name, err)
+		return false
+	}
+
+	timeoutChanged := features.EnsureTimeout(be, beConfig)
+	retryChanged := features.EnsureRetryPolicy(be, beConfig)
+	return timeoutChanged || retryChanged
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"strings"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/backendconfig"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+func TestEnsureBackendConfigFeatures(t *testing.T) {
+	svc := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "svc"}}
+	timeoutSec := int64(45)
+
+	t.Run("valid config is applied", func(t *testing.T) {
+		be := &composite.BackendService{Name: "be-1"}
+		beConfig := &backendconfigv1beta1.BackendConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "beconfig-1"},
+			Spec:       backendconfigv1beta1.BackendConfigSpec{TimeoutSec: &timeoutSec},
+		}
+		recorder := record.NewFakeRecorder(10)
+
+		if changed := ensureBackendConfigFeatures(be, beConfig, svc, recorder); !changed {
+			t.Error("ensureBackendConfigFeatures() = false, want true")
+		}
+		if be.TimeoutSec != timeoutSec {
+			t.Errorf("be.TimeoutSec = %d, want %d", be.TimeoutSec, timeoutSec)
+		}
+		select {
+		case e := <-recorder.Events:
+			t.Errorf("unexpected event recorded for valid config: %s", e)
+		default:
+		}
+	})
+
+	t.Run("invalid config is rejected and left unapplied", func(t *testing.T) {
+		be := &composite.BackendService{Name: "be-2", TimeoutSec: 30}
+		beConfig := &backendconfigv1beta1.BackendConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "beconfig-2"},
+			Spec: backendconfigv1beta1.BackendConfigSpec{
+				RetryPolicy: &backendconfigv1beta1.RetryPolicyConfig{NumRetries: 99},
+			},
+		}
+		recorder := record.NewFakeRecorder(10)
+
+		if changed := ensureBackendConfigFeatures(be, beConfig, svc, recorder); changed {
+			t.Error("ensureBackendConfigFeatures() = true for invalid config, want false")
+		}
+		if be.TimeoutSec != 30 {
+			t.Errorf("be.TimeoutSec = %d, want unchanged 30", be.TimeoutSec)
+		}
+
+		select {
+		case e := <-recorder.Events:
+			if !strings.Contains(e, backendconfig.ReasonInvalid) {
+				t.Errorf("recorded event %q does not reference reason %q", e, backendconfig.ReasonInvalid)
+			}
+		default:
+			t.Error("no event recorded for invalid BackendConfig, want a BackendConfigInvalid event")
+		}
+	})
+}
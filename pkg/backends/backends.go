@@ -0,0 +1,47 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+// backendServiceUpdater abstracts the GCE call used to push a modified
+// composite BackendService. It is satisfied by *composite.BackendServices.
+type backendServiceUpdater interface {
+	Update(be *composite.BackendService) error
+}
+
+// EnsureBackendService reconciles be, the composite BackendService already
+// built for svc, against the BackendConfig (if any) attached to svc. It
+// applies Timeout and RetryPolicy settings from the BackendConfig, and
+// pushes the update to GCE through updater only if something actually
+// changed. This is the last step before be is considered in sync, so it
+// must run on every reconcile, not just when the BackendConfig changes.
+func EnsureBackendService(updater backendServiceUpdater, be *composite.BackendService, beConfig *backendconfigv1beta1.BackendConfig, svc *apiv1.Service, recorder record.EventRecorder) error {
+	if beConfig == nil {
+		return nil
+	}
+	if !ensureBackendConfigFeatures(be, beConfig, svc, recorder) {
+		return nil
+	}
+	return updater.Update(be)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backends
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+type fakeBackendServiceUpdater struct {
+	updated *composite.BackendService
+	calls   int
+}
+
+func (f *fakeBackendServiceUpdater) Update(be *composite.BackendService) error {
+	f.updated = be
+	f.calls++
+	return nil
+}
+
+func TestEnsureBackendServicePushesOnlyWhenChanged(t *testing.T) {
+	svc := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "svc"}}
+	timeoutSec := int64(45)
+	beConfig := &backendconfigv1beta1.BackendConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "beconfig-1"},
+		Spec:       backendconfigv1beta1.BackendConfigSpec{TimeoutSec: &timeoutSec},
+	}
+	be := &composite.BackendService{Name: "be-1"}
+	updater := &fakeBackendServiceUpdater{}
+	recorder := record.NewFakeRecorder(10)
+
+	if err := EnsureBackendService(updater, be, beConfig, svc, recorder); err != nil {
+		t.Fatalf("EnsureBackendService() = %v, want nil", err)
+	}
+	if updater.calls != 1 {
+		t.Errorf("updater.calls = %d, want 1 after a real change", updater.calls)
+	}
+	if be.TimeoutSec != timeoutSec {
+		t.Errorf("be.TimeoutSec = %d, want %d", be.TimeoutSec, timeoutSec)
+	}
+
+	// Reconciling again with no change must not push a redundant update.
+	if err := EnsureBackendService(updater, be, beConfig, svc, recorder); err != nil {
+		t.Fatalf("EnsureBackendService() = %v, want nil", err)
+	}
+	if updater.calls != 1 {
+		t.Errorf("updater.calls = %d, want still 1 after a no-op reconcile", updater.calls)
+	}
+
+	// No BackendConfig attached to the service: nothing to reconcile.
+	updater2 := &fakeBackendServiceUpdater{}
+	if err := EnsureBackendService(updater2, be, nil, svc, recorder); err != nil {
+		t.Fatalf("EnsureBackendService() = %v, want nil", err)
+	}
+	if updater2.calls != 0 {
+		t.Errorf("updater.calls = %d, want 0 with no BackendConfig", updater2.calls)
+	}
+}
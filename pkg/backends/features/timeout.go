@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"reflect"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+// EnsureTimeout reads the Timeout configuration specified in the
+// BackendConfig and applies it to the BackendService. It returns true if
+// the BackendService's TimeoutSec was changed.
+func EnsureTimeout(be *composite.BackendService, beConfig *backendconfigv1beta1.BackendConfig) bool {
+	if beConfig.Spec.TimeoutSec == nil {
+		return false
+	}
+	existing := be.TimeoutSec
+	be.TimeoutSec = *beConfig.Spec.TimeoutSec
+	return existing != be.TimeoutSec
+}
+
+// EnsureRetryPolicy reads the RetryPolicy configuration specified in the
+// BackendConfig and applies it to the BackendService, clearing any
+// previously set retry policy if the BackendConfig no longer specifies
+// one. It returns true if the BackendService was changed.
+func EnsureRetryPolicy(be *composite.BackendService, beConfig *backendconfigv1beta1.BackendConfig) bool {
+	rp := beConfig.Spec.RetryPolicy
+	if rp == nil {
+		changed := be.RetryPolicy != nil
+		be.RetryPolicy = nil
+		return changed
+	}
+
+	existing := be.RetryPolicy
+	be.RetryPolicy = &composite.BackendServiceRetryPolicy{
+		RetryCondition: rp.RetryConditions,
+		NumRetries:     int64(rp.NumRetries),
+	}
+	if rp.PerTryTimeoutSec > 0 {
+		be.RetryPolicy.PerTryTimeout = &composite.Duration{Seconds: int64(rp.PerTryTimeoutSec)}
+	}
+
+	return !reflect.DeepEqual(existing, be.RetryPolicy)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	backendconfigv1beta1 "k8s.io/ingress-gce/pkg/apis/backendconfig/v1beta1"
+	"k8s.io/ingress-gce/pkg/composite"
+)
+
+func TestEnsureTimeout(t *testing.T) {
+	timeoutSec := int64(45)
+	beConfig := &backendconfigv1beta1.BackendConfig{
+		Spec: backendconfigv1beta1.BackendConfigSpec{TimeoutSec: &timeoutSec},
+	}
+	be := &composite.BackendService{TimeoutSec: 30}
+
+	if changed := EnsureTimeout(be, beConfig); !changed {
+		t.Error("EnsureTimeout() = false, want true")
+	}
+	if be.TimeoutSec != timeoutSec {
+		t.Errorf("be.TimeoutSec = %d, want %d", be.TimeoutSec, timeoutSec)
+	}
+
+	if changed := EnsureTimeout(be, beConfig); changed {
+		t.Error("EnsureTimeout() = true on second call with no change, want false")
+	}
+}
+
+func TestEnsureRetryPolicy(t *testing.T) {
+	beConfig := &backendconfigv1beta1.BackendConfig{
+		Spec: backendconfigv1beta1.BackendConfigSpec{
+			RetryPolicy: &backendconfigv1beta1.RetryPolicyConfig{
+				NumRetries:       3,
+				PerTryTimeoutSec: 5,
+				RetryConditions:  []string{"5xx", "gateway-error"},
+			},
+		},
+	}
+	be := &composite.BackendService{}
+
+	if changed := EnsureRetryPolicy(be, beConfig); !changed {
+		t.Error("EnsureRetryPolicy() = false, want true")
+	}
+	if be.RetryPolicy == nil {
+		t.Fatal("be.RetryPolicy = nil, want non-nil")
+	}
+	if be.RetryPolicy.NumRetries != 3 {
+		t.Errorf("be.RetryPolicy.NumRetries = %d, want 3", be.RetryPolicy.NumRetries)
+	}
+	if be.RetryPolicy.PerTryTimeout == nil || be.RetryPolicy.PerTryTimeout.Seconds != 5 {
+		t.Errorf("be.RetryPolicy.PerTryTimeout = %+v, want Seconds: 5", be.RetryPolicy.PerTryTimeout)
+	}
+
+	if changed := EnsureRetryPolicy(be, beConfig); changed {
+		t.Error("EnsureRetryPolicy() = true on second call with no change, want false")
+	}
+
+	// A change to RetryConditions alone, with NumRetries unchanged, must
+	// still be detected so the translator pushes the update to GCE.
+	beConfig.Spec.RetryPolicy.RetryConditions = []string{"retriable-4xx"}
+	if changed := EnsureRetryPolicy(be, beConfig); !changed {
+		t.Error("EnsureRetryPolicy() = false after RetryConditions-only change, want true")
+	}
+
+	// A change to PerTryTimeoutSec alone, with NumRetries and
+	// RetryConditions unchanged, must also be detected.
+	beConfig.Spec.RetryPolicy.PerTryTimeoutSec = 10
+	if changed := EnsureRetryPolicy(be, beConfig); !changed {
+		t.Error("EnsureRetryPolicy() = false after PerTryTimeoutSec-only change, want true")
+	}
+
+	// Removing retryPolicy from the BackendConfig on a later reconcile
+	// must clear the stale policy from the BackendService, not just
+	// report a change while leaving it in place.
+	beConfig.Spec.RetryPolicy = nil
+	if changed := EnsureRetryPolicy(be, beConfig); !changed {
+		t.Error("EnsureRetryPolicy() = false after removing retryPolicy, want true")
+	}
+	if be.RetryPolicy != nil {
+		t.Errorf("be.RetryPolicy = %+v after removing retryPolicy, want nil", be.RetryPolicy)
+	}
+
+	// A no-op reconcile with retryPolicy still unset must not report a
+	// change.
+	if changed := EnsureRetryPolicy(be, beConfig); changed {
+		t.Error("EnsureRetryPolicy() = true with retryPolicy already cleared, want false")
+	}
+}
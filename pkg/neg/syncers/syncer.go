@@ -17,17 +17,91 @@ limitations under the License.
 package syncers
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/golang/glog"
+	"golang.org/x/time/rate"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 )
 
+const (
+	// numSyncWorkers is the number of goroutines dequeuing and processing
+	// sync requests for a single syncer. A NEG syncer only ever enqueues
+	// its own NegSyncerKey, and the workqueue never hands the same key to
+	// two workers at once, so a single worker is enough.
+	numSyncWorkers = 1
+
+	// bucketQPS and bucketBurst bound the aggregate rate at which all NEG
+	// syncers in this process may hit the GCE API, regardless of how many
+	// individual syncer keys are retrying or backing off at once.
+	bucketQPS   = 10
+	bucketBurst = 100
+
+	// jitterFactor adds up to this fraction of random jitter on top of the
+	// rate limiter's computed delay, so that many syncers backing off from
+	// a common upstream failure do not all retry in lockstep.
+	jitterFactor = 0.2
+)
+
+// globalBucketRateLimiter throttles GCE API calls made by every NEG syncer
+// in this process. It is shared by pointer across each syncer's rate
+// limiter so that a storm of updates across many services is globally
+// bounded, not just bounded on a per-key basis.
+var globalBucketRateLimiter = &workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(bucketQPS), bucketBurst)}
+
+// newSyncRateLimiter returns the workqueue.RateLimiter used to schedule
+// retries for a single syncer: per-key exponential backoff (bounded by
+// maxRetryDelay) combined with the process-wide token bucket above, with
+// jitter added to the resulting delay.
+func newSyncRateLimiter() workqueue.RateLimiter {
+	return newSyncRateLimiterWithBucket(globalBucketRateLimiter)
+}
+
+// newSyncRateLimiterWithBucket builds the same per-key exponential backoff
+// plus jitter stack as newSyncRateLimiter, but against the given bucket
+// rate limiter instead of the process-wide global one. It exists so tests
+// can exercise the backoff/jitter behavior against a private bucket,
+// without draining or depending on shared global state.
+func newSyncRateLimiterWithBucket(bucket workqueue.RateLimiter) workqueue.RateLimiter {
+	return &jitteredRateLimiter{
+		RateLimiter: workqueue.NewMaxOfRateLimiter(
+			workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay),
+			bucket,
+		),
+	}
+}
+
+// bucketWaiter is satisfied by *rate.Limiter. It gates every syncFunc
+// invocation (not just retries) on the shared token bucket, so a storm of
+// successful syncs across many syncers is globally throttled against the
+// GCE API, not just a storm of failures. Defined as an interface so tests
+// can inject a private limiter instead of reaching into
+// globalBucketRateLimiter.
+type bucketWaiter interface {
+	Wait(ctx context.Context) error
+}
+
+// jitteredRateLimiter wraps a workqueue.RateLimiter and adds a random
+// fraction of extra delay to every computed backoff, so that many syncers
+// recovering from the same upstream failure do not all retry at the exact
+// same instant.
+type jitteredRateLimiter struct {
+	workqueue.RateLimiter
+}
+
+func (r *jitteredRateLimiter) When(item interface{}) time.Duration {
+	delay := r.RateLimiter.When(item)
+	return time.Duration(float64(delay) * (1 + rand.Float64()*jitterFactor))
+}
+
 // syncer is a NEG syncer skeleton.
 // It handles state transitions and backoff retry operations.
 type syncer struct {
@@ -46,14 +120,32 @@ type syncer struct {
 	stateLock    sync.Mutex
 	stopped      bool
 	shuttingDown bool
+	stopCh       chan struct{}
+
+	// resyncPeriod, when non-zero, forces a full reconcile of this
+	// syncer's key on an interval even when no Sync() signal has arrived,
+	// to catch NEs that drifted out-of-band (e.g. mutated directly in
+	// GCE) or endpoints missed due to informer glitches.
+	resyncPeriod time.Duration
+
+	// deepCheckFunc, when set, is invoked on every resync tick. It
+	// compares the actual NEs in GCE against the desired set computed
+	// from the endpoint informer and returns true when they diverge.
+	deepCheckFunc func() (bool, error)
+
+	// sync signal and retry handling. Sync() enqueues this syncer's own
+	// key; workqueue coalesces repeated signals while a sync is already
+	// pending or in flight into a single dequeue.
+	clock clock.Clock
+	queue workqueue.RateLimitingInterface
 
-	// sync signal and retry handling
-	syncCh  chan interface{}
-	clock   clock.Clock
-	backoff backoffHandler
+	// bucketLimiter gates every syncFunc invocation on the process-wide
+	// token bucket, so successful syncs (not just retries after failure)
+	// are globally throttled against the GCE API.
+	bucketLimiter bucketWaiter
 }
 
-func newSyncer(negSyncerKey NegSyncerKey, networkEndpointGroupName string, serviceLister cache.Indexer, recorder record.EventRecorder) *syncer {
+func newSyncer(negSyncerKey NegSyncerKey, networkEndpointGroupName string, serviceLister cache.Indexer, recorder record.EventRecorder, resyncPeriod time.Duration) *syncer {
 	return &syncer{
 		NegSyncerKey:  negSyncerKey,
 		negName:       networkEndpointGroupName,
@@ -63,10 +155,19 @@ func newSyncer(negSyncerKey NegSyncerKey, networkEndpointGroupName string, servi
 		stopped:       true,
 		shuttingDown:  false,
 		clock:         clock.RealClock{},
-		backoff:       NewExponentialBackendOffHandler(maxRetries, minRetryDelay, maxRetryDelay),
+		resyncPeriod:  resyncPeriod,
+		queue:         workqueue.NewRateLimitingQueue(newSyncRateLimiter()),
+		bucketLimiter: globalBucketRateLimiter.Limiter,
 	}
 }
 
+// setBucketLimiter overrides the bucket rate limiter gating syncFunc
+// invocations. It exists so tests can inject a private limiter instead of
+// depending on the process-wide global one.
+func (s *syncer) setBucketLimiter(b bucketWaiter) {
+	s.bucketLimiter = b
+}
+
 func (s *syncer) Start() error {
 	if !s.IsStopped() {
 		return fmt.Errorf("NEG syncer for %s is already running.", s.NegSyncerKey.String())
@@ -77,50 +178,99 @@ func (s *syncer) Start() error {
 
 	glog.V(2).Infof("Starting NEG syncer for service port %s", s.NegSyncerKey.String())
 	s.init()
+	for i := 0; i < numSyncWorkers; i++ {
+		go s.worker()
+	}
+	s.startResyncTicker()
+	return nil
+}
+
+// startResyncTicker periodically forces a full reconcile of this syncer's
+// key even in steady state, independent of any Sync() signal. It is a
+// no-op when resyncPeriod is zero, stops firing once Stop() is called, and
+// never fires while the syncer is shutting down.
+func (s *syncer) startResyncTicker() {
+	if s.resyncPeriod <= 0 {
+		return
+	}
+	ticker := s.clock.NewTicker(s.resyncPeriod)
 	go func() {
+		defer ticker.Stop()
 		for {
-			// equivalent to never retry
-			retryCh := make(<-chan time.Time)
-			err := s.syncFunc()
-			if err != nil {
-				delay, retryErr := s.backoff.NextRetryDelay()
-				retryMesg := ""
-				if retryErr == ErrRetriesExceeded {
-					retryMesg = "(will not retry)"
-				} else {
-					retryCh = s.clock.After(delay)
-					retryMesg = "(will retry)"
-				}
-
-				if svc := getService(s.serviceLister, s.Namespace, s.Name); svc != nil {
-					s.recorder.Eventf(svc, apiv1.EventTypeWarning, "SyncNetworkEndpointGroupFailed", "Failed to sync NEG %q %s: %v", s.negName, retryMesg, err)
-				}
-			} else {
-				s.backoff.ResetRetryDelay()
-			}
-
 			select {
-			case _, open := <-s.syncCh:
-				if !open {
-					s.stateLock.Lock()
-					s.shuttingDown = false
-					s.stateLock.Unlock()
-					glog.V(2).Infof("Stopping NEG syncer for %s", s.NegSyncerKey.String())
+			case <-s.stopCh:
+				return
+			case <-ticker.C():
+				if s.IsShuttingDown() {
 					return
 				}
-			case <-retryCh:
-				// continue to sync
+				s.resync()
 			}
 		}
 	}()
-	return nil
+}
+
+// resync forces a reconcile of this syncer's key. When a deep check
+// function is configured, it first compares the actual NEs in GCE against
+// the desired set computed from the endpoint informer, emitting a
+// NEGDriftDetected event on the service when they have diverged.
+func (s *syncer) resync() {
+	if s.deepCheckFunc != nil {
+		drifted, err := s.deepCheckFunc()
+		if err != nil {
+			glog.Errorf("Failed to run NEG drift check for %s: %v", s.NegSyncerKey.String(), err)
+		} else if drifted {
+			if svc := getService(s.serviceLister, s.Namespace, s.Name); svc != nil {
+				s.recorder.Eventf(svc, apiv1.EventTypeWarning, "NEGDriftDetected", "NEG %q diverged from desired endpoints, forcing resync", s.negName)
+			}
+		}
+	}
+	s.queue.Add(s.NegSyncerKey)
+}
+
+// worker drains the queue until it is shut down, processing one item
+// (this syncer's key) at a time.
+func (s *syncer) worker() {
+	for s.processNextWorkItem() {
+	}
+	s.stateLock.Lock()
+	s.shuttingDown = false
+	s.stateLock.Unlock()
+	glog.V(2).Infof("Stopping NEG syncer for %s", s.NegSyncerKey.String())
+}
+
+func (s *syncer) processNextWorkItem() bool {
+	key, quit := s.queue.Get()
+	if quit {
+		return false
+	}
+	defer s.queue.Done(key)
+
+	// Gate every actual GCE sync - not just retries after failure - on the
+	// process-wide token bucket, so a storm of successful syncs across
+	// many syncers is globally throttled too.
+	if err := s.bucketLimiter.Wait(context.Background()); err != nil {
+		glog.Errorf("Failed to wait on NEG sync rate limiter for %s: %v", s.NegSyncerKey.String(), err)
+	}
+
+	if err := s.syncFunc(); err != nil {
+		if svc := getService(s.serviceLister, s.Namespace, s.Name); svc != nil {
+			s.recorder.Eventf(svc, apiv1.EventTypeWarning, "SyncNetworkEndpointGroupFailed", "Failed to sync NEG %q (will retry): %v", s.negName, err)
+		}
+		s.queue.AddRateLimited(key)
+		return true
+	}
+
+	s.queue.Forget(key)
+	return true
 }
 
 func (s *syncer) init() {
 	s.stateLock.Lock()
 	defer s.stateLock.Unlock()
 	s.stopped = false
-	s.syncCh = make(chan interface{}, 1)
+	s.stopCh = make(chan struct{})
+	s.queue = workqueue.NewRateLimitingQueue(newSyncRateLimiter())
 }
 
 func (s *syncer) Stop() {
@@ -130,7 +280,8 @@ func (s *syncer) Stop() {
 		glog.V(2).Infof("Stopping NEG syncer for service port %s", s.NegSyncerKey.String())
 		s.stopped = true
 		s.shuttingDown = true
-		close(s.syncCh)
+		close(s.stopCh)
+		s.queue.ShutDown()
 	}
 }
 
@@ -139,12 +290,8 @@ func (s *syncer) Sync() bool {
 		glog.Warningf("NEG syncer for %s is already stopped.", s.NegSyncerKey.String())
 		return false
 	}
-	select {
-	case s.syncCh <- struct{}{}:
-		return true
-	default:
-		return false
-	}
+	s.queue.Add(s.NegSyncerKey)
+	return true
 }
 
 func (s *syncer) IsStopped() bool {
@@ -162,3 +309,10 @@ func (s *syncer) IsShuttingDown() bool {
 func (s *syncer) SetSyncFunc(syncFunc func() error) {
 	s.syncFunc = syncFunc
 }
+
+// SetDeepCheckFunc configures the syncer to run deepCheckFunc on every
+// resync tick, in addition to the normal reconcile. Passing nil (the
+// default) disables deep checking.
+func (s *syncer) SetDeepCheckFunc(deepCheckFunc func() (bool, error)) {
+	s.deepCheckFunc = deepCheckFunc
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// newTestBucketRateLimiter returns a token bucket with the same QPS/burst
+// as the process-wide global one, but private to the caller, so tests can
+// exhaust or otherwise exercise a bucket without affecting other tests.
+func newTestBucketRateLimiter() workqueue.RateLimiter {
+	return &workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(bucketQPS), bucketBurst)}
+}
+
+func newTestSyncer() *syncer {
+	return newSyncer(
+		NegSyncerKey{Namespace: "ns", Name: "svc"},
+		"neg-name",
+		cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		record.NewFakeRecorder(100),
+		0,
+	)
+}
+
+// TestSyncerCoalescesRapidSyncs verifies that a burst of Sync() calls for
+// the same key collapses into a small number of syncFunc invocations,
+// instead of one invocation per Sync() call.
+func TestSyncerCoalescesRapidSyncs(t *testing.T) {
+	s := newTestSyncer()
+
+	var calls int32
+	block := make(chan struct{})
+	s.SetSyncFunc(func() error {
+		atomic.AddInt32(&calls, 1)
+		<-block
+		return nil
+	})
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() = %v, want nil", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Sync()
+		}()
+	}
+	wg.Wait()
+
+	// Unblock the single in-flight syncFunc call; any Sync() calls that
+	// arrived while it was running should have coalesced into at most one
+	// more queued key.
+	close(block)
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+
+	if got := atomic.LoadInt32(&calls); got > 5 {
+		t.Errorf("syncFunc invoked %d times for 1000 coalesced Sync() calls, want <= 5", got)
+	}
+}
+
+// TestSyncerRetryBackoffIsJitteredAndBounded verifies that the retry rate
+// limiter produces an escalating, jittered delay that never exceeds
+// maxRetryDelay plus the configured jitter headroom.
+func TestSyncerRetryBackoffIsJitteredAndBounded(t *testing.T) {
+	limiter := newSyncRateLimiterWithBucket(newTestBucketRateLimiter())
+	defer limiter.Forget("key")
+
+	maxAllowed := time.Duration(float64(maxRetryDelay) * (1 + jitterFactor))
+
+	first := limiter.When("key")
+	var last time.Duration
+	sawJitter := false
+	for i := 0; i < 20; i++ {
+		d := limiter.When("key")
+		if d > maxAllowed {
+			t.Errorf("retry %d: delay = %v, want <= %v", i, d, maxAllowed)
+		}
+		// Once the backoff saturates at maxRetryDelay, only jitter can
+		// push the delay above it.
+		if d > maxRetryDelay {
+			sawJitter = true
+		}
+		last = d
+	}
+
+	if last < first {
+		t.Errorf("last retry delay %v is smaller than first retry delay %v, want backoff to escalate", last, first)
+	}
+	if !sawJitter {
+		t.Errorf("no retry delay exceeded maxRetryDelay %v, want jitter added on top of the saturated backoff", maxRetryDelay)
+	}
+}
+
+// TestGlobalBucketThrottlesConcurrentSyncsAcrossSyncers verifies that the
+// token bucket actually gates the normal Sync() path, end-to-end: two
+// independent syncers sharing one single-token bucket must have their
+// syncFunc invocations serialized roughly a token-refill interval apart,
+// not just their post-failure retries.
+func TestGlobalBucketThrottlesConcurrentSyncsAcrossSyncers(t *testing.T) {
+	// One token, refilling every 50ms, so a second concurrent sync is
+	// forced to wait for the next token instead of running immediately.
+	const refillInterval = 50 * time.Millisecond
+	bucket := rate.NewLimiter(rate.Every(refillInterval), 1)
+	// Drain the initial burst token so both syncers start from empty.
+	if err := bucket.Wait(context.Background()); err != nil {
+		t.Fatalf("bucket.Wait() = %v, want nil", err)
+	}
+
+	newThrottledSyncer := func(name string) (*syncer, chan time.Time) {
+		s := newSyncer(
+			NegSyncerKey{Namespace: "ns", Name: name},
+			"neg-"+name,
+			cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+			record.NewFakeRecorder(10),
+			0,
+		)
+		s.setBucketLimiter(bucket)
+		synced := make(chan time.Time, 1)
+		s.SetSyncFunc(func() error {
+			synced <- time.Now()
+			return nil
+		})
+		return s, synced
+	}
+
+	a, syncedA := newThrottledSyncer("svc-a")
+	b, syncedB := newThrottledSyncer("svc-b")
+
+	if err := a.Start(); err != nil {
+		t.Fatalf("a.Start() = %v, want nil", err)
+	}
+	defer a.Stop()
+	if err := b.Start(); err != nil {
+		t.Fatalf("b.Start() = %v, want nil", err)
+	}
+	defer b.Stop()
+
+	a.Sync()
+	b.Sync()
+
+	var tA, tB time.Time
+	select {
+	case tA = <-syncedA:
+	case <-time.After(2 * time.Second):
+		t.Fatal("syncer a's syncFunc never ran")
+	}
+	select {
+	case tB = <-syncedB:
+	case <-time.After(2 * time.Second):
+		t.Fatal("syncer b's syncFunc never ran")
+	}
+
+	// Both syncFunc calls ran (the bucket never drops a sync, only
+	// delays it), but since they draw from the same single-token bucket
+	// the two must be separated by roughly one refill interval -
+	// proving the bucket gates the normal, non-retry Sync() path across
+	// syncers and not just the AddRateLimited failure path.
+	gap := tB.Sub(tA)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap < refillInterval/2 {
+		t.Errorf("gap between two syncers' throttled syncs = %v, want >= ~%v (one shared-bucket refill interval)", gap, refillInterval/2)
+	}
+}
+
+// TestSyncerResyncTicker verifies that a configured ResyncPeriod forces a
+// sync on each tick, even with no Sync() calls, and that ticks stop once
+// the syncer is stopped.
+func TestSyncerResyncTicker(t *testing.T) {
+	s := newTestSyncer()
+	fakeClock := clock.NewFakeClock(time.Now())
+	s.clock = fakeClock
+	s.resyncPeriod = time.Minute
+
+	var calls int32
+	syncedCh := make(chan struct{}, 10)
+	s.SetSyncFunc(func() error {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case syncedCh <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() = %v, want nil", err)
+	}
+
+	// Allow the fake clock's ticker to register before stepping it.
+	for !fakeClock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Step(time.Minute)
+
+	select {
+	case <-syncedCh:
+	case <-time.After(time.Second):
+		t.Fatalf("resync did not fire syncFunc within 1s of the ResyncPeriod tick")
+	}
+
+	s.Stop()
+	time.Sleep(20 * time.Millisecond)
+
+	afterStop := atomic.LoadInt32(&calls)
+	fakeClock.Step(time.Minute)
+	time.Sleep(20 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&calls); got != afterStop {
+		t.Errorf("syncFunc invoked %d more times after Stop(), want 0", got-afterStop)
+	}
+}
+
+// TestSyncerResyncDeepCheckDrift verifies that a resync tick runs the
+// configured deep check and emits a NEGDriftDetected event on the service
+// when it reports drift.
+func TestSyncerResyncDeepCheckDrift(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	svc := &apiv1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "svc"}}
+	if err := indexer.Add(svc); err != nil {
+		t.Fatalf("indexer.Add() = %v, want nil", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+
+	s := newSyncer(
+		NegSyncerKey{Namespace: "ns", Name: "svc"},
+		"neg-name",
+		indexer,
+		recorder,
+		0,
+	)
+	fakeClock := clock.NewFakeClock(time.Now())
+	s.clock = fakeClock
+	s.resyncPeriod = time.Minute
+	s.SetDeepCheckFunc(func() (bool, error) { return true, nil })
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() = %v, want nil", err)
+	}
+	defer s.Stop()
+
+	for !fakeClock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Step(time.Minute)
+
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, "NEGDriftDetected") {
+			t.Errorf("recorded event %q, want it to reference NEGDriftDetected", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event recorded within 1s of a drifted resync tick, want a NEGDriftDetected event")
+	}
+}